@@ -0,0 +1,13 @@
+package openai
+
+// Client is the entry point for calling the OpenAI API.
+type Client struct {
+	config ClientConfig
+
+	// imageCache, if non-nil, caches CreateImage/CreateEditImage/
+	// CreateVariImage responses. See WithImageCache.
+	imageCache ImageCache
+	// imagePHashIndex backs perceptual near-duplicate matching for
+	// imageCache. See WithImageCache.
+	imagePHashIndex *perceptualHashIndex
+}
@@ -0,0 +1,177 @@
+package openai
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// defaultImagePerceptualThreshold is the default Hamming-distance tolerance
+// used when matching a new input image against previously cached ones.
+const defaultImagePerceptualThreshold = 4
+
+// defaultImagePerceptualIndexSize is the default cap on how many perceptual
+// hashes perceptualHashIndex keeps in memory. See WithImagePerceptualIndexSize.
+const defaultImagePerceptualIndexSize = 10000
+
+// perceptualHashIndex tracks the perceptual hash behind each image cache
+// key in memory, so near-duplicate image edit/variation inputs can reuse a
+// cached result even though their exact-match cache keys differ. It's kept
+// client-side regardless of the ImageCache backend in use, since Redis/S3
+// and friends have no notion of Hamming distance.
+//
+// Entries are bounded by maxEntries on a least-recently-used basis: a
+// long-lived Client with WithImageCache enabled would otherwise grow this
+// index forever, one entry per unique edit/variation input, for the life of
+// the process.
+type perceptualHashIndex struct {
+	mu         sync.Mutex
+	hashes     map[string]uint64
+	elems      map[string]*list.Element
+	order      *list.List // front = most recently used
+	threshold  int
+	maxEntries int
+}
+
+func newPerceptualHashIndex(threshold int) *perceptualHashIndex {
+	return &perceptualHashIndex{
+		hashes:     make(map[string]uint64),
+		elems:      make(map[string]*list.Element),
+		order:      list.New(),
+		threshold:  threshold,
+		maxEntries: defaultImagePerceptualIndexSize,
+	}
+}
+
+func (idx *perceptualHashIndex) findNear(hash uint64) (string, bool) {
+	return idx.findNearWithPrefix("", hash)
+}
+
+// findNearWithPrefix is findNear restricted to keys that start with prefix.
+func (idx *perceptualHashIndex) findNearWithPrefix(prefix string, hash uint64) (string, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for key, h := range idx.hashes {
+		if strings.HasPrefix(key, prefix) && HammingDistance(hash, h) <= idx.threshold {
+			idx.touch(key)
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func (idx *perceptualHashIndex) record(key string, hash uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, exists := idx.hashes[key]; !exists && idx.maxEntries > 0 && len(idx.hashes) >= idx.maxEntries {
+		idx.evictOldest()
+	}
+	idx.hashes[key] = hash
+	idx.touch(key)
+}
+
+// touch marks key as most recently used, starting to track it if it isn't
+// already. Callers must hold idx.mu.
+func (idx *perceptualHashIndex) touch(key string) {
+	if elem, ok := idx.elems[key]; ok {
+		idx.order.MoveToFront(elem)
+		return
+	}
+	idx.elems[key] = idx.order.PushFront(key)
+}
+
+// evictOldest drops the least recently used entry. Callers must hold idx.mu.
+func (idx *perceptualHashIndex) evictOldest() {
+	oldest := idx.order.Back()
+	if oldest == nil {
+		return
+	}
+	key, _ := oldest.Value.(string)
+	idx.order.Remove(oldest)
+	delete(idx.elems, key)
+	delete(idx.hashes, key)
+}
+
+// WithImageCache enables response caching for CreateImage, CreateEditImage,
+// and CreateVariImage: identical (or, for edits/variations, perceptually
+// near-identical) requests are served from cache instead of hitting the
+// API. It returns c for chaining:
+//
+//	client := openai.NewClient(token).WithImageCache(cache)
+func (c *Client) WithImageCache(cache ImageCache) *Client {
+	c.imageCache = cache
+	if c.imagePHashIndex == nil {
+		c.imagePHashIndex = newPerceptualHashIndex(defaultImagePerceptualThreshold)
+	}
+	return c
+}
+
+// WithImagePerceptualThreshold overrides the default Hamming-distance
+// tolerance (4 of 64 bits) used to match a new edit/variation input image
+// against cached ones. Call it after WithImageCache.
+func (c *Client) WithImagePerceptualThreshold(bits int) *Client {
+	if c.imagePHashIndex == nil {
+		c.imagePHashIndex = newPerceptualHashIndex(bits)
+		return c
+	}
+	c.imagePHashIndex.threshold = bits
+	return c
+}
+
+// WithImagePerceptualIndexSize overrides the default cap (10000 entries) on
+// how many perceptual hashes the in-memory near-duplicate index keeps,
+// evicting the least recently used entry once the cap is reached. Call it
+// after WithImageCache.
+func (c *Client) WithImagePerceptualIndexSize(maxEntries int) *Client {
+	if c.imagePHashIndex == nil {
+		c.imagePHashIndex = newPerceptualHashIndex(defaultImagePerceptualThreshold)
+	}
+	c.imagePHashIndex.mu.Lock()
+	c.imagePHashIndex.maxEntries = maxEntries
+	c.imagePHashIndex.mu.Unlock()
+	return c
+}
+
+// imageCacheLookup buffers r fully up front (computeImageHash needs the
+// whole image anyway), checks the cache for a near-duplicate hit keyed on
+// textKey plus the buffered image's perceptual hash, and returns a fresh
+// reader replaying the full buffered bytes for the caller to upload. Only
+// the primary image is hashed; mask content (for edits) isn't part of the
+// cache key.
+//
+// The image is read into memory before hashing, rather than hashing through
+// a TeeReader and replaying whatever the hash happened to consume: only
+// png/jpeg/gif decoders are registered (see image_preprocess.go), so
+// computeImageHash fails fast on webp/bmp/tiff input after reading just the
+// format-sniff prefix. Replaying only that prefix would silently truncate
+// the upload for every such format; buffering up front guarantees replay
+// always carries the complete original image regardless of whether hashing
+// succeeds.
+func (c *Client) imageCacheLookup(r io.Reader, textKey string) (cached ImageResponse, hit bool, replay io.Reader, cacheKey string) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		// Couldn't buffer the input at all; let the caller try uploading
+		// whatever's left of r rather than failing the request outright.
+		return ImageResponse{}, false, r, ""
+	}
+	replay = bytes.NewReader(data)
+
+	hash, err := computeImageHash(bytes.NewReader(data))
+	if err != nil {
+		// Unrecognized image format: skip caching for this call, but still
+		// upload the full bytes we already buffered.
+		return ImageResponse{}, false, replay, ""
+	}
+
+	cacheKey = fmt.Sprintf("%s:%016x", textKey, hash)
+	if matchKey, ok := c.imagePHashIndex.findNearWithPrefix(textKey+":", hash); ok {
+		if resp, ok := c.imageCache.Get(matchKey); ok {
+			return resp, true, replay, cacheKey
+		}
+	}
+	c.imagePHashIndex.record(cacheKey, hash)
+	return ImageResponse{}, false, replay, cacheKey
+}
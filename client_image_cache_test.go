@@ -0,0 +1,110 @@
+package openai
+
+import (
+	"bytes"
+	"image/color"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// TestImageCacheLookupDoesNotTruncateUnrecognizedFormat is a regression test
+// for a bug where imageCacheLookup replayed only the bytes computeImageHash
+// had managed to read before failing on an unrecognized format (e.g.
+// webp/bmp/tiff), silently truncating the upload to a few KB.
+func TestImageCacheLookupDoesNotTruncateUnrecognizedFormat(t *testing.T) {
+	c := &Client{imagePHashIndex: newPerceptualHashIndex(defaultImagePerceptualThreshold)}
+
+	// Random bytes decode as none of the registered image formats
+	// (png/jpeg/gif), so computeImageHash fails after reading only a small
+	// sniff prefix.
+	data := make([]byte, 100000)
+	if _, err := rand.New(rand.NewSource(1)).Read(data); err != nil {
+		t.Fatalf("generating test data: %v", err)
+	}
+
+	_, hit, replay, cacheKey := c.imageCacheLookup(bytes.NewReader(data), "text-key")
+	if hit {
+		t.Fatal("expected no cache hit for an unrecognized format")
+	}
+	if cacheKey != "" {
+		t.Fatalf("expected no cache key to be assigned for an unrecognized format, got %q", cacheKey)
+	}
+
+	replayed, err := io.ReadAll(replay)
+	if err != nil {
+		t.Fatalf("reading replay: %v", err)
+	}
+	if !bytes.Equal(replayed, data) {
+		t.Fatalf("replay carried %d bytes, want the full %d bytes of the original image", len(replayed), len(data))
+	}
+}
+
+func TestImageCacheLookupHitsOnNearDuplicate(t *testing.T) {
+	c := &Client{
+		imageCache:      newTestImageCache(),
+		imagePHashIndex: newPerceptualHashIndex(defaultImagePerceptualThreshold),
+	}
+
+	data := encodePNG(t, solidImage(64, 64, color.RGBA{R: 20, G: 120, B: 200, A: 255}))
+
+	_, hit, _, cacheKey := c.imageCacheLookup(bytes.NewReader(data), "text-key")
+	if hit {
+		t.Fatal("expected no hit on the first lookup")
+	}
+	c.imageCache.Put(cacheKey, ImageResponse{Created: 1})
+
+	_, hit, _, _ = c.imageCacheLookup(bytes.NewReader(data), "text-key")
+	if !hit {
+		t.Fatal("expected a hit when looking up the same image again")
+	}
+}
+
+func TestPerceptualHashIndexEvictsLeastRecentlyUsed(t *testing.T) {
+	idx := newPerceptualHashIndex(0)
+	idx.maxEntries = 3
+
+	idx.record("a", 1)
+	idx.record("b", 2)
+	idx.record("c", 3)
+	idx.record("d", 4) // over capacity: evicts "a", the least recently used
+
+	if len(idx.hashes) != 3 {
+		t.Fatalf("expected 3 entries after eviction, got %d", len(idx.hashes))
+	}
+	if _, ok := idx.hashes["a"]; ok {
+		t.Fatal("expected \"a\" to have been evicted")
+	}
+
+	idx2 := newPerceptualHashIndex(0)
+	idx2.maxEntries = 3
+	idx2.record("a", 1)
+	idx2.record("b", 2)
+	idx2.record("c", 3)
+	idx2.findNear(1) // touches "a", making "b" the least recently used
+	idx2.record("d", 4)
+
+	if _, ok := idx2.hashes["a"]; !ok {
+		t.Fatal("expected \"a\" to survive eviction after being touched")
+	}
+	if _, ok := idx2.hashes["b"]; ok {
+		t.Fatal("expected \"b\" to have been evicted as least recently used")
+	}
+}
+
+type testImageCache struct {
+	entries map[string]ImageResponse
+}
+
+func newTestImageCache() *testImageCache {
+	return &testImageCache{entries: make(map[string]ImageResponse)}
+}
+
+func (c *testImageCache) Get(key string) (ImageResponse, bool) {
+	resp, ok := c.entries[key]
+	return resp, ok
+}
+
+func (c *testImageCache) Put(key string, resp ImageResponse) {
+	c.entries[key] = resp
+}
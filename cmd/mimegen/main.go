@@ -0,0 +1,102 @@
+// Command mimegen reads internal/mime.types and emits internal/mime_types.go,
+// a generated lookup table used in place of ad-hoc MIME detection. Run it via
+// `go generate ./...` after editing internal/mime.types.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+var (
+	in  = flag.String("in", "internal/mime.types", "path to the mime.types source table")
+	out = flag.String("out", "internal/mime_types.go", "path to write the generated Go source")
+)
+
+const tmplSrc = `// Code generated by cmd/mimegen from mime.types. DO NOT EDIT.
+
+package internal
+
+// extToMime maps a lowercase file extension (without the leading dot) to its
+// MIME type.
+var extToMime = map[string]string{
+{{- range .Ext }}
+	{{ printf "%q" .Ext }}: {{ printf "%q" .Mime }},
+{{- end }}
+}
+
+// mimeToExt maps a MIME type to its canonical file extension (without the
+// leading dot).
+var mimeToExt = map[string]string{
+{{- range .Mime }}
+	{{ printf "%q" .Mime }}: {{ printf "%q" .Ext }},
+{{- end }}
+}
+`
+
+type extEntry struct {
+	Ext  string
+	Mime string
+}
+
+func main() {
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatalf("mimegen: %v", err)
+	}
+	defer f.Close()
+
+	var extEntries []extEntry
+	var mimeEntries []extEntry
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			log.Fatalf("mimegen: malformed line %q", line)
+		}
+
+		mime := fields[0]
+		exts := fields[1:]
+		mimeEntries = append(mimeEntries, extEntry{Ext: exts[0], Mime: mime})
+		for _, ext := range exts {
+			extEntries = append(extEntries, extEntry{Ext: ext, Mime: mime})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("mimegen: %v", err)
+	}
+
+	sort.Slice(extEntries, func(i, j int) bool { return extEntries[i].Ext < extEntries[j].Ext })
+	sort.Slice(mimeEntries, func(i, j int) bool { return mimeEntries[i].Mime < mimeEntries[j].Mime })
+
+	tmpl := template.Must(template.New("mime_types").Parse(tmplSrc))
+
+	w, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("mimegen: %v", err)
+	}
+	defer w.Close()
+
+	if err := tmpl.Execute(w, struct {
+		Ext  []extEntry
+		Mime []extEntry
+	}{Ext: extEntries, Mime: mimeEntries}); err != nil {
+		log.Fatalf("mimegen: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "mimegen: wrote %s from %s\n", *out, *in)
+}
@@ -0,0 +1,25 @@
+package openai
+
+import "net/http"
+
+// ClientConfig holds the dependencies and defaults Client needs that aren't
+// tied to any single request: the HTTP transport to use and where
+// diagnostic output goes. Use DefaultConfig to build one, then override
+// individual fields as needed.
+type ClientConfig struct {
+	HTTPClient *http.Client
+
+	// Logger receives Client's internal diagnostics (request parameters,
+	// form fields omitted for a model, request failures). Defaults to a no-op
+	// implementation via DefaultConfig.
+	Logger Logger
+}
+
+// DefaultConfig returns a ClientConfig with sane defaults: a plain
+// *http.Client and a no-op Logger.
+func DefaultConfig() ClientConfig {
+	return ClientConfig{
+		HTTPClient: &http.Client{},
+		Logger:     noopLogger{},
+	}
+}
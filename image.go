@@ -1,13 +1,13 @@
 package openai
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strconv"
+
+	"github.com/sashabaranov/go-openai/internal"
 )
 
 // Image sizes defined by the OpenAI API.
@@ -132,6 +132,14 @@ type ImageEditRequest struct {
 
 // CreateImage - API call to create an image. This is the main endpoint of the DALL-E API.
 func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (response ImageResponse, err error) {
+	var cacheKey string
+	if c.imageCache != nil {
+		cacheKey = cacheKeyForImageRequest(request)
+		if cached, ok := c.imageCache.Get(cacheKey); ok {
+			return cached, nil
+		}
+	}
+
 	urlSuffix := "/images/generations"
 	req, err := c.newRequest(
 		ctx,
@@ -144,6 +152,9 @@ func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (respons
 	}
 
 	err = c.sendRequest(req, &response)
+	if err == nil && c.imageCache != nil {
+		c.imageCache.Put(cacheKey, response)
+	}
 	return
 }
 
@@ -157,204 +168,179 @@ type ImageVariRequest struct {
 	User           string    `json:"user,omitempty"`
 }
 
-// CreateVariImage - API call to create an image variation. This is the main endpoint of the DALL-E API.
-// Use abbreviations(vari for variation) because ci-lint has a single-line length limit ...
-func (c *Client) CreateVariImage(ctx context.Context, request ImageVariRequest) (response ImageResponse, err error) {
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
-
-	// image, filename is not required
-	err = builder.CreateFormFileReader("image", request.Image, "")
-	if err != nil {
-		return
-	}
-
-	err = builder.WriteField("n", strconv.Itoa(request.N))
+// doMultipart builds a multipart/form-data POST request to url for the
+// caller to send, streaming the body through an io.Pipe as build writes to
+// the FormBuilder on a separate goroutine, rather than buffering the whole
+// form in memory first. This
+// keeps large file uploads (images, masks, audio) from doubling memory use.
+//
+// The goroutine is only started once newRequest has succeeded: nothing
+// reads from pr until the returned request is actually sent, so starting it
+// earlier would leak it (blocked on its first pw.Write) whenever newRequest
+// or one of the request options fails.
+func (c *Client) doMultipart(
+	ctx context.Context,
+	url string,
+	build func(builder internal.FormBuilder) error,
+) (req *http.Request, err error) {
+	pr, pw := io.Pipe()
+	builder := internal.NewStreamingFormBuilder(pw)
+
+	req, err = c.newRequest(
+		ctx,
+		http.MethodPost,
+		url,
+		withBody(pr),
+		withContentType(builder.FormDataContentType()),
+	)
 	if err != nil {
-		return
+		_ = pr.CloseWithError(err)
+		return nil, err
 	}
 
-	err = builder.WriteField("size", request.Size)
-	if err != nil {
-		return
-	}
+	go func() {
+		buildErr := build(builder)
+		if buildErr == nil {
+			buildErr = builder.Close()
+		}
+		_ = pw.CloseWithError(buildErr)
+	}()
 
-	err = builder.WriteField("response_format", request.ResponseFormat)
-	if err != nil {
-		return
-	}
+	return req, nil
+}
 
-	err = builder.Close()
-	if err != nil {
-		return
+// CreateVariImage - API call to create an image variation. This is the main endpoint of the DALL-E API.
+// Use abbreviations(vari for variation) because ci-lint has a single-line length limit ...
+func (c *Client) CreateVariImage(ctx context.Context, request ImageVariRequest) (response ImageResponse, err error) {
+	var cacheKey string
+	if c.imageCache != nil {
+		var cached ImageResponse
+		var hit bool
+		cached, hit, request.Image, cacheKey = c.imageCacheLookup(request.Image, cacheKeyForImageVariRequest(request))
+		if hit {
+			return cached, nil
+		}
 	}
 
-	req, err := c.newRequest(
+	req, err := c.doMultipart(
 		ctx,
-		http.MethodPost,
 		c.fullURL("/images/variations", withModel(request.Model)),
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
+		func(builder internal.FormBuilder) error {
+			// image, filename is not required
+			if err := builder.CreateFormFileReader("image", request.Image, ""); err != nil {
+				return err
+			}
+			if err := builder.WriteField("n", strconv.Itoa(request.N)); err != nil {
+				return err
+			}
+			if err := builder.WriteField("size", request.Size); err != nil {
+				return err
+			}
+			return builder.WriteField("response_format", request.ResponseFormat)
+		},
 	)
 	if err != nil {
 		return
 	}
 
 	err = c.sendRequest(req, &response)
+	if err == nil && cacheKey != "" {
+		c.imageCache.Put(cacheKey, response)
+	}
 	return
 }
 
 // CreateEditImage - API call to create an image. This is the main endpoint of the DALL-E API.
 func (c *Client) CreateEditImage(ctx context.Context, request ImageEditRequest) (response ImageResponse, err error) {
-	// Debug logging
-	fmt.Printf("[DEBUG] CreateEditImage called with: Model=%s, Prompt=%s, Size=%s, N=%d, Quality='%s', ResponseFormat='%s'\n",
-		request.Model, request.Prompt, request.Size, request.N, request.Quality, request.ResponseFormat)
-
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
+	logger := c.config.Logger
+	logger.Debugf("openai: CreateEditImage model=%s size=%s n=%d quality=%q response_format=%q",
+		request.Model, request.Size, request.N, request.Quality, request.ResponseFormat)
 
-	// Try to get file size for debugging and ensure file is at beginning
-	if file, ok := request.Image.(*os.File); ok {
-		if stat, err := file.Stat(); err == nil {
-			fmt.Printf("[DEBUG] Image file size: %d bytes\n", stat.Size())
+	url := c.fullURL("/images/edits")
+	caps := capabilitiesForImageEditModel(request.Model)
+
+	var cacheKey string
+	if c.imageCache != nil {
+		var cached ImageResponse
+		var hit bool
+		cached, hit, request.Image, cacheKey = c.imageCacheLookup(request.Image, cacheKeyForImageEditRequest(request))
+		if hit {
+			return cached, nil
 		}
-		// Reset file position to beginning - critical for reading
-		offset, err := file.Seek(0, 0)
-		fmt.Printf("[DEBUG] File seek to beginning: offset=%d, err=%v\n", offset, err)
-
-		// Read first few bytes to verify file content
-		testBytes := make([]byte, 16)
-		n, err := file.Read(testBytes)
-		fmt.Printf("[DEBUG] First %d bytes: %x, err=%v\n", n, testBytes[:n], err)
-		// Seek back to beginning after test read
-		file.Seek(0, 0)
 	}
 
-	// Check if it's a NamedReader
-	if namedReader, ok := request.Image.(interface{ Name() string }); ok {
-		fmt.Printf("[DEBUG] Image has filename: %s\n", namedReader.Name())
-	}
-
-	// Use CreateFormFileReader which will auto-detect MIME type and set proper filename
-	err = builder.CreateFormFileReader("image", request.Image, "")
-	if err != nil {
-		fmt.Printf("[DEBUG] Error adding image to form: %v\n", err)
-		return
-	}
+	req, err := c.doMultipart(ctx, url, func(builder internal.FormBuilder) error {
+		if err := builder.CreateFormFileReader("image", request.Image, ""); err != nil {
+			return fmt.Errorf("adding image to form: %w", err)
+		}
 
-	// mask, it is optional
-	if request.Mask != nil {
-		// Try to get mask file info
-		if file, ok := request.Mask.(*os.File); ok {
-			if stat, err := file.Stat(); err == nil {
-				fmt.Printf("[DEBUG] Mask file size: %d bytes\n", stat.Size())
+		// mask, it is optional
+		if request.Mask != nil {
+			if err := builder.CreateFormFileReader("mask", request.Mask, ""); err != nil {
+				return fmt.Errorf("adding mask to form: %w", err)
 			}
-			// Reset file position to beginning
-			offset, err := file.Seek(0, 0)
-			fmt.Printf("[DEBUG] Mask file seek to beginning: offset=%d, err=%v\n", offset, err)
 		}
 
-		// Use CreateFormFileReader which will auto-detect MIME type and set proper filename
-		err = builder.CreateFormFileReader("mask", request.Mask, "")
-		if err != nil {
-			return
+		if err := builder.WriteField("prompt", request.Prompt); err != nil {
+			return err
 		}
-	}
 
-	err = builder.WriteField("prompt", request.Prompt)
-	if err != nil {
-		return
-	}
-
-	// Add model field to form data
-	if request.Model != "" {
-		err = builder.WriteField("model", request.Model)
-		if err != nil {
-			return
+		if request.Model != "" {
+			if err := builder.WriteField("model", request.Model); err != nil {
+				return err
+			}
 		}
-	}
 
-	err = builder.WriteField("n", strconv.Itoa(request.N))
-	if err != nil {
-		return
-	}
+		if err := builder.WriteField("n", strconv.Itoa(request.N)); err != nil {
+			return err
+		}
 
-	err = builder.WriteField("size", request.Size)
-	if err != nil {
-		return
-	}
+		if err := builder.WriteField("size", request.Size); err != nil {
+			return err
+		}
 
-	// CRITICAL: gpt-image-1 does NOT support response_format parameter at all
-	// Python library completely omits this parameter for gpt-image-1
-	if request.ResponseFormat != "" && request.Model != CreateImageModelGptImage1 {
-		fmt.Printf("[DEBUG] Adding response_format: %s (model supports it)\n", request.ResponseFormat)
-		err = builder.WriteField("response_format", request.ResponseFormat)
-		if err != nil {
-			return
+		if request.ResponseFormat != "" {
+			if caps.SupportsResponseFormat {
+				if err := builder.WriteField("response_format", request.ResponseFormat); err != nil {
+					return err
+				}
+			} else {
+				logger.Debugf("openai: model %s does not support response_format, omitting", request.Model)
+			}
 		}
-	} else if request.Model == CreateImageModelGptImage1 {
-		fmt.Printf("[DEBUG] Skipping response_format completely for gpt-image-1 (not supported)\n")
-	}
 
-	// CRITICAL: gpt-image-1 does NOT support quality parameter in the same way
-	// Python library filters this out for gpt-image-1
-	if request.Quality != "" && request.Model != CreateImageModelGptImage1 {
-		fmt.Printf("[DEBUG] Adding quality: %s (model supports it)\n", request.Quality)
-		err = builder.WriteField("quality", request.Quality)
-		if err != nil {
-			return
+		if request.Quality != "" {
+			if caps.SupportsQuality {
+				if err := builder.WriteField("quality", request.Quality); err != nil {
+					return err
+				}
+			} else {
+				logger.Debugf("openai: model %s does not support quality, omitting", request.Model)
+			}
 		}
-	} else if request.Quality != "" && request.Model == CreateImageModelGptImage1 {
-		fmt.Printf("[DEBUG] Skipping quality for gpt-image-1 (not supported in this context)\n")
-	}
 
-	// Add user field if specified
-	if request.User != "" {
-		err = builder.WriteField("user", request.User)
-		if err != nil {
-			return
+		if request.User != "" {
+			if err := builder.WriteField("user", request.User); err != nil {
+				return err
+			}
 		}
-	}
 
-	err = builder.Close()
+		return nil
+	})
 	if err != nil {
+		logger.Errorf("openai: CreateEditImage failed building request: %v", err)
 		return
 	}
 
-	// Debug: print the body size
-	fmt.Printf("[DEBUG] Form body size: %d bytes\n", body.Len())
-
-	url := c.fullURL("/images/edits")
-	fmt.Printf("[DEBUG] Making request to URL: %s\n", url)
-	fmt.Printf("[DEBUG] Content-Type: %s\n", builder.FormDataContentType())
-
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		url,
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
-	)
+	err = c.sendRequest(req, &response)
 	if err != nil {
-		fmt.Printf("[DEBUG] Error creating request: %v\n", err)
+		logger.Errorf("openai: CreateEditImage request failed: %v", err)
 		return
 	}
-
-	fmt.Printf("[DEBUG] Sending request to OpenAI API...\n")
-	fmt.Printf("[DEBUG] Request headers: %v\n", req.Header)
-
-	err = c.sendRequest(req, &response)
-	if err != nil {
-		fmt.Printf("[DEBUG] Error from OpenAI API: %v\n", err)
-		fmt.Printf("[DEBUG] Error type: %T\n", err)
-		// Try to get more details about the error
-		if apiErr, ok := err.(*APIError); ok {
-			fmt.Printf("[DEBUG] API Error details - Code: %s, Message: %s, Type: %s\n",
-				apiErr.Code, apiErr.Message, apiErr.Type)
-		}
-	} else {
-		fmt.Printf("[DEBUG] Successfully received response from OpenAI API\n")
-		fmt.Printf("[DEBUG] Response data count: %d\n", len(response.Data))
+	if cacheKey != "" {
+		c.imageCache.Put(cacheKey, response)
 	}
+
+	logger.Debugf("openai: CreateEditImage received %d image(s)", len(response.Data))
 	return
 }
@@ -0,0 +1,93 @@
+package openai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ImageCache stores and retrieves ImageResponse values by an opaque cache
+// key, letting repeated image requests (generations, edits, variations)
+// skip a round trip to the API entirely. Implementations can back this with
+// disk, Redis, S3, or anything else that can store a string-keyed blob; see
+// FileImageCache for the on-disk default.
+type ImageCache interface {
+	Get(key string) (ImageResponse, bool)
+	Put(key string, resp ImageResponse)
+}
+
+// FileImageCache is an ImageCache backed by one JSON file per key under Dir.
+type FileImageCache struct {
+	Dir string
+}
+
+// NewFileImageCache returns a FileImageCache rooted at dir, creating dir if
+// it doesn't already exist.
+func NewFileImageCache(dir string) (*FileImageCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("openai: creating image cache dir: %w", err)
+	}
+	return &FileImageCache{Dir: dir}, nil
+}
+
+func (c *FileImageCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
+
+// Get implements ImageCache.
+func (c *FileImageCache) Get(key string) (ImageResponse, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return ImageResponse{}, false
+	}
+	var resp ImageResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return ImageResponse{}, false
+	}
+	return resp, true
+}
+
+// Put implements ImageCache.
+func (c *FileImageCache) Put(key string, resp ImageResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(c.path(key), data, 0o644)
+}
+
+// cacheKeyForImageRequest hashes req's JSON encoding, excluding User (which
+// varies per caller and shouldn't affect cache hits), into a stable key.
+func cacheKeyForImageRequest(req ImageRequest) string {
+	req.User = ""
+	return jsonCacheKey(req)
+}
+
+// cacheKeyForImageEditRequest is cacheKeyForImageRequest's counterpart for
+// edit/variation requests, which additionally carry non-serializable
+// io.Reader fields that must be excluded; the image itself is represented
+// separately via its perceptual hash.
+func cacheKeyForImageEditRequest(req ImageEditRequest) string {
+	req.User = ""
+	req.Image = nil
+	req.Mask = nil
+	return jsonCacheKey(req)
+}
+
+func cacheKeyForImageVariRequest(req ImageVariRequest) string {
+	req.User = ""
+	req.Image = nil
+	return jsonCacheKey(req)
+}
+
+func jsonCacheKey(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,140 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai/internal"
+)
+
+// Bytes returns d's image content as raw bytes along with its detected MIME
+// type, regardless of whether the response used response_format=url
+// (fetched via client's HTTPClient) or b64_json (decoded in place).
+func (d *ImageResponseDataInner) Bytes(ctx context.Context, client *Client) ([]byte, string, error) {
+	if d.B64JSON != "" {
+		data, err := base64.StdEncoding.DecodeString(d.B64JSON)
+		if err != nil {
+			return nil, "", fmt.Errorf("openai: decoding b64_json: %w", err)
+		}
+		return data, http.DetectContentType(data), nil
+	}
+
+	if d.URL == "" {
+		return nil, "", fmt.Errorf("openai: image data has neither b64_json nor url set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.URL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("openai: building image download request: %w", err)
+	}
+
+	resp, err := client.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("openai: downloading image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("openai: downloading image: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("openai: reading downloaded image: %w", err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+// SaveAll writes every image in r to dir, naming each file by substituting
+// its index into pattern (e.g. pattern "image-%d" -> "image-0.png",
+// "image-1.png", ...), and returns the written paths in order. The
+// extension is derived from each image's detected MIME type via the
+// generated MIME table (see cmd/mimegen), falling back to "bin" for
+// unrecognized types.
+func (r *ImageResponse) SaveAll(ctx context.Context, client *Client, dir string, pattern string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("openai: creating output dir: %w", err)
+	}
+
+	paths := make([]string, 0, len(r.Data))
+	for i := range r.Data {
+		data, mimeType, err := r.Data[i].Bytes(ctx, client)
+		if err != nil {
+			return paths, fmt.Errorf("openai: saving image %d: %w", i, err)
+		}
+
+		ext, ok := internal.ExtensionForMimeType(mimeType)
+		if !ok {
+			ext = "bin"
+		}
+
+		name := fmt.Sprintf(pattern, i) + "." + ext
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return paths, fmt.Errorf("openai: writing %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// ImageRequestOption configures an ImageRequest built by NewImageRequest.
+type ImageRequestOption func(*ImageRequest)
+
+// WithImagePrompt sets the request's prompt.
+func WithImagePrompt(prompt string) ImageRequestOption {
+	return func(r *ImageRequest) { r.Prompt = prompt }
+}
+
+// WithImageModel sets the request's model.
+func WithImageModel(model string) ImageRequestOption {
+	return func(r *ImageRequest) { r.Model = model }
+}
+
+// WithImageN sets the number of images to generate.
+func WithImageN(n int) ImageRequestOption {
+	return func(r *ImageRequest) { r.N = n }
+}
+
+// WithImageSize sets the requested image size.
+func WithImageSize(size string) ImageRequestOption {
+	return func(r *ImageRequest) { r.Size = size }
+}
+
+// WithImageResponseFormat sets the requested response format.
+func WithImageResponseFormat(format string) ImageRequestOption {
+	return func(r *ImageRequest) { r.ResponseFormat = format }
+}
+
+// NewImageRequest builds an ImageRequest by applying opts in order to its
+// zero value.
+func NewImageRequest(opts ...ImageRequestOption) ImageRequest {
+	var request ImageRequest
+	for _, opt := range opts {
+		opt(&request)
+	}
+	return request
+}
+
+// CreateImageAndDownload combines CreateImage and SaveAll: it creates the
+// image(s) described by request, then downloads and writes them to dir
+// (named by the pattern "image-%d"), returning the written file paths. This
+// is the single most common downstream workflow, so it's provided as a
+// one-call convenience.
+func (c *Client) CreateImageAndDownload(ctx context.Context, request ImageRequest, dir string) ([]string, error) {
+	response, err := c.CreateImage(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return response.SaveAll(ctx, c, dir, "image-%d")
+}
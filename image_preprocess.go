@@ -0,0 +1,300 @@
+package openai
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	// Registers the GIF decoder with image.Decode/image.DecodeConfig.
+	_ "image/gif"
+
+	"github.com/sashabaranov/go-openai/internal"
+)
+
+// ImageFitMode controls how PreprocessImage reconciles an input image's
+// dimensions with the requested target size.
+type ImageFitMode string
+
+const (
+	// ImageFitContain scales the image up or down to fit within the target size,
+	// preserving aspect ratio. The result may be smaller than the target
+	// in one dimension.
+	ImageFitContain ImageFitMode = "contain"
+	// ImageFitCover scales the image to fill the target size, preserving
+	// aspect ratio, and crops the overflow.
+	ImageFitCover ImageFitMode = "cover"
+	// ImageFitStretch scales each dimension independently to match the
+	// target size exactly, ignoring aspect ratio.
+	ImageFitStretch ImageFitMode = "stretch"
+)
+
+// ImagePreprocessOptions configures PreprocessImage.
+type ImagePreprocessOptions struct {
+	// Model, if set, constrains TargetWidth/TargetHeight to a size the
+	// model's /images/edits or /images/variations endpoint actually accepts
+	// (see imageEditSizesByModel in model_capabilities.go). If
+	// TargetWidth/TargetHeight are both zero, the model's preferred size is
+	// selected automatically; otherwise PreprocessImage rejects a
+	// TargetWidth/TargetHeight pair the model doesn't support. Models with
+	// no known size table are left unconstrained. Leave empty to skip this
+	// entirely and resize to whatever TargetWidth/TargetHeight say.
+	Model string
+	// TargetWidth and TargetHeight are the desired output dimensions in
+	// pixels. Leave both zero to skip resizing entirely, or to let Model
+	// pick a default size.
+	TargetWidth  int
+	TargetHeight int
+	// Fit controls how the source image is reconciled with the target
+	// size. Defaults to ImageFitContain if empty.
+	Fit ImageFitMode
+	// OutputFormat is one of CreateImageOutputFormatPNG or
+	// CreateImageOutputFormatJPEG. WEBP cannot be produced because the Go
+	// standard library has no WEBP encoder. Defaults to
+	// CreateImageOutputFormatPNG if empty.
+	OutputFormat string
+	// Quality is the JPEG quality (1-100) used when OutputFormat is
+	// CreateImageOutputFormatJPEG. Ignored otherwise. Defaults to 90.
+	Quality int
+	// StripAlpha flattens any alpha channel onto an opaque white
+	// background, which dall-e-2 edit masks require.
+	StripAlpha bool
+	// MaxBytes, if non-zero, caps the size of the re-encoded image. For
+	// JPEG output, quality is lowered in steps until the image fits or
+	// quality bottoms out at 10; for PNG output MaxBytes is only checked,
+	// since PNG has no quality knob to trade against size.
+	MaxBytes int64
+}
+
+// PreprocessImage decodes r, and if opts requires resizing, cropping, alpha
+// stripping or re-encoding, produces a new reader carrying the transformed
+// image. It first inspects the image's dimensions and format cheaply via
+// image.DecodeConfig on a TeeReader, so inputs that already satisfy opts are
+// passed through without a full decode/re-encode round trip.
+//
+// If opts.Model is set, TargetWidth/TargetHeight are auto-selected or
+// validated against that model's accepted edit/variation sizes before any
+// of the above, so a caller can't hand dall-e-2/gpt-image-1 a size their
+// edit endpoint will 400 on.
+//
+// The returned reader implements Name() (via internal.NamedReader) with an
+// extension matching the output format, so CreateFormFileReader picks the
+// correct MIME type without needing to sniff the body.
+func PreprocessImage(r io.Reader, opts ImagePreprocessOptions) (io.Reader, error) {
+	if opts.Fit == "" {
+		opts.Fit = ImageFitContain
+	}
+	if opts.OutputFormat == "" {
+		opts.OutputFormat = CreateImageOutputFormatPNG
+	}
+	if opts.Quality == 0 {
+		opts.Quality = 90
+	}
+	if opts.OutputFormat == CreateImageOutputFormatWEBP {
+		return nil, fmt.Errorf("openai: PreprocessImage cannot encode WEBP output: " +
+			"the Go standard library provides no WEBP encoder")
+	}
+	if opts.Model != "" {
+		if opts.TargetWidth == 0 && opts.TargetHeight == 0 {
+			opts.TargetWidth, opts.TargetHeight, _ = defaultImageEditSize(opts.Model)
+		} else if !isAllowedImageEditSize(opts.Model, opts.TargetWidth, opts.TargetHeight) {
+			return nil, fmt.Errorf("openai: %dx%d is not a size %s's edit/variation endpoint accepts",
+				opts.TargetWidth, opts.TargetHeight, opts.Model)
+		}
+	}
+
+	var buf bytes.Buffer
+	cfg, format, err := image.DecodeConfig(io.TeeReader(r, &buf))
+	if err != nil {
+		return nil, fmt.Errorf("openai: failed to inspect image: %w", err)
+	}
+	rest := io.MultiReader(&buf, r)
+
+	needsResize := opts.TargetWidth != 0 && opts.TargetHeight != 0 &&
+		(cfg.Width != opts.TargetWidth || cfg.Height != opts.TargetHeight)
+	needsReencode := format != opts.OutputFormat
+	if !needsResize && !needsReencode && !opts.StripAlpha && opts.MaxBytes == 0 {
+		ext := extensionForOutputFormat(opts.OutputFormat)
+		return internal.NewNamedReader(rest, "image"+ext), nil
+	}
+
+	img, _, err := image.Decode(rest)
+	if err != nil {
+		return nil, fmt.Errorf("openai: failed to decode image: %w", err)
+	}
+
+	if needsResize {
+		img = fitImage(img, opts.TargetWidth, opts.TargetHeight, opts.Fit)
+	}
+	if opts.StripAlpha {
+		img = flattenAlpha(img)
+	}
+
+	encoded, err := encodeWithMaxBytes(img, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := extensionForOutputFormat(opts.OutputFormat)
+	return internal.NewNamedReader(bytes.NewReader(encoded), "image"+ext), nil
+}
+
+func extensionForOutputFormat(format string) string {
+	switch format {
+	case CreateImageOutputFormatJPEG:
+		return ".jpg"
+	default:
+		return ".png"
+	}
+}
+
+// fitImage resizes img to targetW x targetH according to fit, using a
+// bilinear resampler, cropping the overflow for ImageFitCover.
+func fitImage(img image.Image, targetW, targetH int, fit ImageFitMode) image.Image {
+	srcBounds := img.Bounds()
+	srcW, srcH := srcBounds.Dx(), srcBounds.Dy()
+
+	switch fit {
+	case ImageFitStretch:
+		return resizeBilinear(img, targetW, targetH)
+	case ImageFitCover:
+		scale := maxFloat(float64(targetW)/float64(srcW), float64(targetH)/float64(srcH))
+		resized := resizeBilinear(img, int(float64(srcW)*scale+0.5), int(float64(srcH)*scale+0.5))
+		return cropCenter(resized, targetW, targetH)
+	default: // ImageFitContain
+		scale := minFloat(float64(targetW)/float64(srcW), float64(targetH)/float64(srcH))
+		return resizeBilinear(img, int(float64(srcW)*scale+0.5), int(float64(srcH)*scale+0.5))
+	}
+}
+
+// resizeBilinear returns a copy of img scaled to w x h using bilinear
+// interpolation over the source pixels.
+func resizeBilinear(img image.Image, w, h int) image.Image {
+	if w <= 0 {
+		w = 1
+	}
+	if h <= 0 {
+		h = 1
+	}
+	src := img.Bounds()
+	srcW, srcH := src.Dx(), src.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	xRatio := float64(srcW) / float64(w)
+	yRatio := float64(srcH) / float64(h)
+
+	for y := 0; y < h; y++ {
+		srcY := (float64(y) + 0.5) * yRatio
+		y0 := clampInt(int(srcY), 0, srcH-1)
+		y1 := clampInt(y0+1, 0, srcH-1)
+		yFrac := srcY - float64(y0)
+
+		for x := 0; x < w; x++ {
+			srcX := (float64(x) + 0.5) * xRatio
+			x0 := clampInt(int(srcX), 0, srcW-1)
+			x1 := clampInt(x0+1, 0, srcW-1)
+			xFrac := srcX - float64(x0)
+
+			c00 := img.At(src.Min.X+x0, src.Min.Y+y0)
+			c10 := img.At(src.Min.X+x1, src.Min.Y+y0)
+			c01 := img.At(src.Min.X+x0, src.Min.Y+y1)
+			c11 := img.At(src.Min.X+x1, src.Min.Y+y1)
+
+			dst.Set(x, y, bilerpColor(c00, c10, c01, c11, xFrac, yFrac))
+		}
+	}
+	return dst
+}
+
+func bilerpColor(c00, c10, c01, c11 color.Color, xFrac, yFrac float64) color.Color {
+	r00, g00, b00, a00 := c00.RGBA()
+	r10, g10, b10, a10 := c10.RGBA()
+	r01, g01, b01, a01 := c01.RGBA()
+	r11, g11, b11, a11 := c11.RGBA()
+
+	lerp := func(v00, v10, v01, v11 uint32) uint8 {
+		top := float64(v00)*(1-xFrac) + float64(v10)*xFrac
+		bottom := float64(v01)*(1-xFrac) + float64(v11)*xFrac
+		return uint8((top*(1-yFrac) + bottom*yFrac) / 257) // 16-bit -> 8-bit
+	}
+
+	return color.RGBA{
+		R: lerp(r00, r10, r01, r11),
+		G: lerp(g00, g10, g01, g11),
+		B: lerp(b00, b10, b01, b11),
+		A: lerp(a00, a10, a01, a11),
+	}
+}
+
+func cropCenter(img image.Image, w, h int) image.Image {
+	b := img.Bounds()
+	x0 := b.Min.X + (b.Dx()-w)/2
+	y0 := b.Min.Y + (b.Dy()-h)/2
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), img, image.Pt(x0, y0), draw.Src)
+	return dst
+}
+
+func flattenAlpha(img image.Image) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, b, img, b.Min, draw.Over)
+	return dst
+}
+
+func encodeWithMaxBytes(img image.Image, opts ImagePreprocessOptions) ([]byte, error) {
+	if opts.OutputFormat != CreateImageOutputFormatJPEG {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("openai: failed to encode PNG: %w", err)
+		}
+		if opts.MaxBytes != 0 && int64(buf.Len()) > opts.MaxBytes {
+			return nil, fmt.Errorf("openai: encoded PNG is %d bytes, exceeding MaxBytes %d; "+
+				"PNG has no quality knob to trade against size, so request a smaller "+
+				"TargetWidth/TargetHeight or CreateImageOutputFormatJPEG instead",
+				buf.Len(), opts.MaxBytes)
+		}
+		return buf.Bytes(), nil
+	}
+
+	quality := opts.Quality
+	for {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("openai: failed to encode JPEG: %w", err)
+		}
+		if opts.MaxBytes == 0 || int64(buf.Len()) <= opts.MaxBytes || quality <= 10 {
+			return buf.Bytes(), nil
+		}
+		quality -= 10
+	}
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
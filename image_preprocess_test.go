@@ -0,0 +1,188 @@
+package openai
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func solidImage(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encoding test PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decodePNG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decoding result PNG: %v", err)
+	}
+	return img
+}
+
+func TestPreprocessImageResizeFitModes(t *testing.T) {
+	src := encodePNG(t, solidImage(40, 20, color.RGBA{R: 255, A: 255}))
+
+	tests := []struct {
+		name       string
+		fit        ImageFitMode
+		wantWidth  int
+		wantHeight int
+	}{
+		{"contain", ImageFitContain, 10, 5},
+		{"cover", ImageFitCover, 10, 10},
+		{"stretch", ImageFitStretch, 10, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := PreprocessImage(bytes.NewReader(src), ImagePreprocessOptions{
+				TargetWidth:  10,
+				TargetHeight: 10,
+				Fit:          tt.fit,
+			})
+			if err != nil {
+				t.Fatalf("PreprocessImage: %v", err)
+			}
+			var buf bytes.Buffer
+			if _, err := buf.ReadFrom(out); err != nil {
+				t.Fatalf("reading result: %v", err)
+			}
+			img := decodePNG(t, buf.Bytes())
+			b := img.Bounds()
+			if b.Dx() != tt.wantWidth || b.Dy() != tt.wantHeight {
+				t.Fatalf("got %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestPreprocessImagePassthroughWhenNoChangeNeeded(t *testing.T) {
+	src := encodePNG(t, solidImage(10, 10, color.RGBA{G: 255, A: 255}))
+
+	out, err := PreprocessImage(bytes.NewReader(src), ImagePreprocessOptions{})
+	if err != nil {
+		t.Fatalf("PreprocessImage: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(out); err != nil {
+		t.Fatalf("reading result: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), src) {
+		t.Fatalf("expected passthrough to return the original bytes unchanged")
+	}
+}
+
+func TestPreprocessImageStripAlpha(t *testing.T) {
+	transparent := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	src := encodePNG(t, transparent)
+
+	out, err := PreprocessImage(bytes.NewReader(src), ImagePreprocessOptions{StripAlpha: true})
+	if err != nil {
+		t.Fatalf("PreprocessImage: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(out); err != nil {
+		t.Fatalf("reading result: %v", err)
+	}
+	img := decodePNG(t, buf.Bytes())
+	_, _, _, a := img.At(0, 0).RGBA()
+	if a != 0xffff {
+		t.Fatalf("expected alpha-stripped pixel to be fully opaque, got alpha %d", a)
+	}
+}
+
+func TestEncodeWithMaxBytesJPEGStepsDownQuality(t *testing.T) {
+	img := solidImage(64, 64, color.RGBA{R: 10, G: 200, B: 30, A: 255})
+
+	encoded, err := encodeWithMaxBytes(img, ImagePreprocessOptions{
+		OutputFormat: CreateImageOutputFormatJPEG,
+		Quality:      90,
+		// JPEG headers alone take roughly 650 bytes here; quality 90 encodes
+		// just over this cap, so fitting under it needs a lower quality.
+		MaxBytes: 658,
+	})
+	if err != nil {
+		t.Fatalf("encodeWithMaxBytes: %v", err)
+	}
+	if int64(len(encoded)) > 658 {
+		t.Fatalf("encoded JPEG is %d bytes, want <= 658 after quality step-down", len(encoded))
+	}
+}
+
+func TestPreprocessImageModelAutoSelectsDefaultSize(t *testing.T) {
+	src := encodePNG(t, solidImage(10, 10, color.RGBA{R: 255, A: 255}))
+
+	out, err := PreprocessImage(bytes.NewReader(src), ImagePreprocessOptions{Model: CreateImageModelGptImage1})
+	if err != nil {
+		t.Fatalf("PreprocessImage: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(out); err != nil {
+		t.Fatalf("reading result: %v", err)
+	}
+	img := decodePNG(t, buf.Bytes())
+	b := img.Bounds()
+	if b.Dx() != 1024 || b.Dy() != 1024 {
+		t.Fatalf("got %dx%d, want gpt-image-1's default 1024x1024", b.Dx(), b.Dy())
+	}
+}
+
+func TestPreprocessImageModelRejectsUnsupportedSize(t *testing.T) {
+	src := encodePNG(t, solidImage(10, 10, color.RGBA{R: 255, A: 255}))
+
+	_, err := PreprocessImage(bytes.NewReader(src), ImagePreprocessOptions{
+		Model:        CreateImageModelDallE2,
+		TargetWidth:  300,
+		TargetHeight: 300,
+	})
+	if err == nil {
+		t.Fatal("expected an error for a size dall-e-2's edit endpoint doesn't accept")
+	}
+}
+
+func TestPreprocessImageReencodesOnFormatMismatchEvenWithoutResize(t *testing.T) {
+	src := encodePNG(t, solidImage(10, 10, color.RGBA{B: 255, A: 255}))
+
+	out, err := PreprocessImage(bytes.NewReader(src), ImagePreprocessOptions{
+		OutputFormat: CreateImageOutputFormatJPEG,
+	})
+	if err != nil {
+		t.Fatalf("PreprocessImage: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(out); err != nil {
+		t.Fatalf("reading result: %v", err)
+	}
+	if _, format, err := image.Decode(bytes.NewReader(buf.Bytes())); err != nil || format != "jpeg" {
+		t.Fatalf("expected the output to actually be re-encoded as JPEG, got format %q, err %v", format, err)
+	}
+}
+
+func TestEncodeWithMaxBytesPNGErrorsWhenTooLarge(t *testing.T) {
+	img := solidImage(64, 64, color.RGBA{R: 10, G: 200, B: 30, A: 255})
+
+	_, err := encodeWithMaxBytes(img, ImagePreprocessOptions{
+		OutputFormat: CreateImageOutputFormatPNG,
+		MaxBytes:     1,
+	})
+	if err == nil {
+		t.Fatal("expected an error when the encoded PNG exceeds MaxBytes")
+	}
+}
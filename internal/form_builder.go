@@ -1,6 +1,9 @@
-package openai
+//go:generate go run ../cmd/mimegen -in mime.types -out mime_types.go
+
+package internal
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -30,6 +33,19 @@ func NewFormBuilder(body io.Writer) *DefaultFormBuilder {
 	}
 }
 
+// NewStreamingFormBuilder returns a FormBuilder that writes directly to pw.
+// Callers are expected to drive it from a separate goroutine than the one
+// reading from the paired *io.PipeReader, and to call Close (and then
+// pw.Close/pw.CloseWithError) once every field has been written, so the
+// reader side doesn't block waiting on data that will never arrive. This
+// lets large files (e.g. image uploads) stream into the request body
+// instead of being buffered in a bytes.Buffer first.
+func NewStreamingFormBuilder(pw *io.PipeWriter) *DefaultFormBuilder {
+	return &DefaultFormBuilder{
+		writer: multipart.NewWriter(pw),
+	}
+}
+
 func (fb *DefaultFormBuilder) CreateFormFile(fieldname string, file *os.File) error {
 	return fb.createFormFile(fieldname, file, file.Name())
 }
@@ -40,20 +56,30 @@ func escapeQuotes(s string) string {
 	return quoteEscaper.Replace(s)
 }
 
-// detectMimeType attempts to detect MIME type from file content
-func detectMimeType(r io.Reader) (string, io.Reader, error) {
-	// Read first 512 bytes for MIME type detection
+// mimeTypeByExtension looks up the MIME type for filename's extension in the
+// table generated from mime.types (see cmd/mimegen). Returns "", false if
+// filename has no recognized extension.
+func mimeTypeByExtension(filename string) (string, bool) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if ext == "" {
+		return "", false
+	}
+	mimeType, ok := extToMime[ext]
+	return mimeType, ok
+}
+
+// sniffMimeType detects a MIME type from content, for readers that carry no
+// filename to look up. It reads up to 512 bytes for http.DetectContentType
+// and returns a reader that replays those bytes before the rest of r.
+func sniffMimeType(r io.Reader) (string, io.Reader, error) {
 	buffer := make([]byte, 512)
 	n, err := r.Read(buffer)
 	if err != nil && err != io.EOF {
 		return "", nil, err
 	}
 
-	// Detect MIME type
 	mimeType := http.DetectContentType(buffer[:n])
-
-	// Create a new reader that includes the read bytes
-	newReader := io.MultiReader(strings.NewReader(string(buffer[:n])), r)
+	newReader := io.MultiReader(bytes.NewReader(buffer[:n]), r)
 
 	return mimeType, newReader, nil
 }
@@ -62,8 +88,21 @@ func detectMimeType(r io.Reader) (string, io.Reader, error) {
 // The filename in parameters can be an empty string.
 // The filename in Content-Disposition is required, But it can be an empty string.
 func (fb *DefaultFormBuilder) CreateFormFileReader(fieldname string, r io.Reader, filename string) error {
-	// Auto-detect MIME type if not provided
-	mimeType, newReader, err := detectMimeType(r)
+	if filename == "" {
+		if namedReader, ok := r.(interface{ Name() string }); ok {
+			filename = namedReader.Name()
+		}
+	}
+
+	// Prefer the extension table whenever we have a filename to look up;
+	// it's cheaper and doesn't depend on platform-specific sniffing rules.
+	// Anonymous readers (no filename available) still fall back to content
+	// sniffing.
+	if mimeType, ok := mimeTypeByExtension(filename); ok {
+		return fb.CreateFormFileReaderWithMimeType(fieldname, r, filename, mimeType)
+	}
+
+	mimeType, newReader, err := sniffMimeType(r)
 	if err != nil {
 		return fmt.Errorf("failed to detect MIME type: %w", err)
 	}
@@ -78,24 +117,14 @@ func (fb *DefaultFormBuilder) CreateFormFileReaderWithMimeType(fieldname string,
 		filename = namedReader.Name()
 	}
 
-	// If still no filename, provide a default based on MIME type
+	// If still no filename, derive one from the canonical extension for
+	// mimeType in the generated table.
 	if filename == "" {
-		switch mimeType {
-		case "image/png":
-			filename = "image.png"
-		case "image/jpeg", "image/jpg":
-			filename = "image.jpg"
-		case "image/gif":
-			filename = "image.gif"
-		case "image/webp":
-			filename = "image.webp"
-		case "image/bmp":
-			filename = "image.bmp"
-		case "image/tiff":
-			filename = "image.tiff"
-		default:
-			filename = "file.bin"
+		ext, ok := mimeToExt[mimeType]
+		if !ok {
+			ext = "bin"
 		}
+		filename = "file." + ext
 	}
 
 	h := make(textproto.MIMEHeader)
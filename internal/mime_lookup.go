@@ -0,0 +1,17 @@
+package internal
+
+// ExtensionForMimeType returns the canonical file extension (without the
+// leading dot) registered for mimeType in mime.types, and whether one was
+// found.
+func ExtensionForMimeType(mimeType string) (string, bool) {
+	ext, ok := mimeToExt[mimeType]
+	return ext, ok
+}
+
+// MimeTypeForExtension returns the MIME type registered for ext (without
+// the leading dot) in mime.types, and whether one was found. Callers should
+// lowercase ext first; lookups are case-sensitive.
+func MimeTypeForExtension(ext string) (string, bool) {
+	mimeType, ok := extToMime[ext]
+	return mimeType, ok
+}
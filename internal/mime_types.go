@@ -0,0 +1,48 @@
+// Code generated by cmd/mimegen from mime.types. DO NOT EDIT.
+
+package internal
+
+// extToMime maps a lowercase file extension (without the leading dot) to its
+// MIME type.
+var extToMime = map[string]string{
+	"bin":  "application/octet-stream",
+	"bmp":  "image/bmp",
+	"flac": "audio/flac",
+	"gif":  "image/gif",
+	"jpeg": "image/jpeg",
+	"jpg":  "image/jpeg",
+	"m4a":  "audio/mp4",
+	"mp3":  "audio/mpeg",
+	"mp4":  "video/mp4",
+	"mpeg": "video/mpeg",
+	"mpga": "audio/mpeg",
+	"oga":  "audio/ogg",
+	"ogg":  "audio/ogg",
+	"png":  "image/png",
+	"tif":  "image/tiff",
+	"tiff": "image/tiff",
+	"wav":  "audio/wav",
+	"weba": "audio/webm",
+	"webm": "audio/webm",
+	"webp": "image/webp",
+}
+
+// mimeToExt maps a MIME type to its canonical file extension (without the
+// leading dot).
+var mimeToExt = map[string]string{
+	"application/octet-stream": "bin",
+	"audio/flac":               "flac",
+	"audio/mp4":                "m4a",
+	"audio/mpeg":               "mp3",
+	"audio/ogg":                "ogg",
+	"audio/wav":                "wav",
+	"audio/webm":               "webm",
+	"image/bmp":                "bmp",
+	"image/gif":                "gif",
+	"image/jpeg":               "jpg",
+	"image/png":                "png",
+	"image/tiff":               "tiff",
+	"image/webp":               "webp",
+	"video/mp4":                "mp4",
+	"video/mpeg":               "mpeg",
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestMimeTypesMatchSourceTable re-parses mime.types independently of
+// cmd/mimegen and asserts the checked-in mime_types.go agrees with it,
+// catching drift if one is edited without regenerating the other.
+func TestMimeTypesMatchSourceTable(t *testing.T) {
+	f, err := os.Open("mime.types")
+	if err != nil {
+		t.Fatalf("opening mime.types: %v", err)
+	}
+	defer f.Close()
+
+	wantExtToMime := make(map[string]string)
+	wantMimeToExt := make(map[string]string)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			t.Fatalf("malformed line %q", line)
+		}
+
+		mime := fields[0]
+		exts := fields[1:]
+		wantMimeToExt[mime] = exts[0]
+		for _, ext := range exts {
+			wantExtToMime[ext] = mime
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scanning mime.types: %v", err)
+	}
+
+	if len(extToMime) != len(wantExtToMime) {
+		t.Errorf("extToMime has %d entries, mime.types implies %d", len(extToMime), len(wantExtToMime))
+	}
+	for ext, mime := range wantExtToMime {
+		if got := extToMime[ext]; got != mime {
+			t.Errorf("extToMime[%q] = %q, want %q", ext, got, mime)
+		}
+	}
+
+	if len(mimeToExt) != len(wantMimeToExt) {
+		t.Errorf("mimeToExt has %d entries, mime.types implies %d", len(mimeToExt), len(wantMimeToExt))
+	}
+	for mime, ext := range wantMimeToExt {
+		if got := mimeToExt[mime]; got != ext {
+			t.Errorf("mimeToExt[%q] = %q, want %q", mime, got, ext)
+		}
+	}
+}
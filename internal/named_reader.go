@@ -0,0 +1,23 @@
+package internal
+
+import "io"
+
+// NamedReader wraps an io.Reader with an explicit file name, so callers that
+// only have an in-memory or generated image (rather than an *os.File) can
+// still tell CreateFormFileReader what filename and, by extension, MIME type
+// to use.
+type NamedReader struct {
+	io.Reader
+	name string
+}
+
+// NewNamedReader returns a NamedReader that reads from r and reports name
+// when its Name method is called.
+func NewNamedReader(r io.Reader, name string) *NamedReader {
+	return &NamedReader{Reader: r, name: name}
+}
+
+// Name returns the file name associated with the reader.
+func (n *NamedReader) Name() string {
+	return n.name
+}
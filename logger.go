@@ -0,0 +1,19 @@
+package openai
+
+// Logger is the structured logging interface Client uses for its internal
+// diagnostics (request parameters, form fields omitted for a model, request
+// failures). Implement it to route that output into your own logging stack.
+// ClientConfig defaults to noopLogger, which discards everything, so Client
+// is silent unless a Logger is configured explicitly.
+type Logger interface {
+	Debugf(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// noopLogger is the default Logger: it discards every call.
+type noopLogger struct{}
+
+func (noopLogger) Debugf(string, ...any) {}
+func (noopLogger) Warnf(string, ...any)  {}
+func (noopLogger) Errorf(string, ...any) {}
@@ -0,0 +1,86 @@
+package openai
+
+// imageEditCapabilities describes which optional /images/edits form fields
+// a given model accepts, so new models can
+// be onboarded by adding a table entry instead of another
+// `if request.Model == ...` branch.
+type imageEditCapabilities struct {
+	SupportsResponseFormat bool
+	SupportsQuality        bool
+}
+
+// defaultImageEditCapabilities applies to any model without a more specific
+// entry in imageEditCapabilitiesByModel.
+var defaultImageEditCapabilities = imageEditCapabilities{
+	SupportsResponseFormat: true,
+	SupportsQuality:        true,
+}
+
+// imageEditCapabilitiesByModel holds the exceptions to defaultImageEditCapabilities.
+var imageEditCapabilitiesByModel = map[string]imageEditCapabilities{
+	// gpt-image-1 rejects response_format and quality as form fields
+	// outright; it always returns b64_json and infers quality from the
+	// prompt/size instead.
+	CreateImageModelGptImage1: {
+		SupportsResponseFormat: false,
+		SupportsQuality:        false,
+	},
+}
+
+func capabilitiesForImageEditModel(model string) imageEditCapabilities {
+	if caps, ok := imageEditCapabilitiesByModel[model]; ok {
+		return caps
+	}
+	return defaultImageEditCapabilities
+}
+
+// imageEditSize is one TargetWidth/TargetHeight pair a model's
+// /images/edits and /images/variations endpoints accept.
+type imageEditSize struct {
+	Width  int
+	Height int
+}
+
+// imageEditSizesByModel holds the sizes each model's edit/variation
+// endpoints accept, in preference order (the first entry is used as the
+// default when a caller doesn't specify TargetWidth/TargetHeight). Models
+// without an entry here are unconstrained as far as PreprocessImage knows,
+// so any requested size is allowed through unchecked.
+var imageEditSizesByModel = map[string][]imageEditSize{
+	CreateImageModelDallE2: {
+		{Width: 1024, Height: 1024},
+		{Width: 512, Height: 512},
+		{Width: 256, Height: 256},
+	},
+	CreateImageModelGptImage1: {
+		{Width: 1024, Height: 1024},
+		{Width: 1536, Height: 1024},
+		{Width: 1024, Height: 1536},
+	},
+}
+
+// defaultImageEditSize returns the preferred TargetWidth/TargetHeight for
+// model, if model has a known size table.
+func defaultImageEditSize(model string) (width, height int, ok bool) {
+	sizes, ok := imageEditSizesByModel[model]
+	if !ok || len(sizes) == 0 {
+		return 0, 0, false
+	}
+	return sizes[0].Width, sizes[0].Height, true
+}
+
+// isAllowedImageEditSize reports whether width x height is one of model's
+// accepted edit/variation sizes. Models without a known size table are
+// treated as allowing anything, since there's nothing to validate against.
+func isAllowedImageEditSize(model string, width, height int) bool {
+	sizes, ok := imageEditSizesByModel[model]
+	if !ok {
+		return true
+	}
+	for _, s := range sizes {
+		if s.Width == width && s.Height == height {
+			return true
+		}
+	}
+	return false
+}
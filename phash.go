@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"image"
+	"io"
+	"math"
+	"math/bits"
+)
+
+const (
+	phashSampleSize = 32
+	phashBlockSize  = 8
+)
+
+// computeImageHash returns a 64-bit perceptual hash (pHash) of the image in
+// r, suitable for finding near-duplicate inputs via HammingDistance. It
+// downsamples to a phashSampleSize x phashSampleSize grayscale image, runs a
+// 2D DCT, keeps the top-left phashBlockSize x phashBlockSize block of
+// coefficients (the lowest frequencies), and sets each hash bit to whether
+// that coefficient exceeds the mean of the block excluding the DC term.
+func computeImageHash(r io.Reader) (uint64, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return 0, err
+	}
+
+	small := resizeBilinear(img, phashSampleSize, phashSampleSize)
+	gray := make([][]float64, phashSampleSize)
+	for y := 0; y < phashSampleSize; y++ {
+		gray[y] = make([]float64, phashSampleSize)
+		for x := 0; x < phashSampleSize; x++ {
+			r16, g16, b16, _ := small.At(x, y).RGBA()
+			gray[y][x] = 0.299*float64(r16>>8) + 0.587*float64(g16>>8) + 0.114*float64(b16>>8)
+		}
+	}
+
+	coeffs := dct2D(gray)
+
+	var block [phashBlockSize * phashBlockSize]float64
+	var sum float64
+	for y := 0; y < phashBlockSize; y++ {
+		for x := 0; x < phashBlockSize; x++ {
+			v := coeffs[y][x]
+			block[y*phashBlockSize+x] = v
+			if x != 0 || y != 0 {
+				sum += v
+			}
+		}
+	}
+	mean := sum / float64(len(block)-1)
+
+	var hash uint64
+	for i, v := range block {
+		if v > mean {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash, nil
+}
+
+// HammingDistance returns the number of differing bits between two
+// perceptual hashes computed by computeImageHash.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// dct2D applies a separable 2D DCT-II to matrix (rows then columns).
+func dct2D(matrix [][]float64) [][]float64 {
+	n := len(matrix)
+
+	rows := make([][]float64, n)
+	for y := 0; y < n; y++ {
+		rows[y] = dct1D(matrix[y])
+	}
+
+	out := make([][]float64, n)
+	for y := 0; y < n; y++ {
+		out[y] = make([]float64, n)
+	}
+	for x := 0; x < n; x++ {
+		col := make([]float64, n)
+		for y := 0; y < n; y++ {
+			col[y] = rows[y][x]
+		}
+		col = dct1D(col)
+		for y := 0; y < n; y++ {
+			out[y][x] = col[y]
+		}
+	}
+	return out
+}
+
+// dct1D applies a 1D DCT-II with orthonormal scaling to input.
+func dct1D(input []float64) []float64 {
+	n := len(input)
+	output := make([]float64, n)
+	for k := 0; k < n; k++ {
+		var sum float64
+		for i := 0; i < n; i++ {
+			sum += input[i] * math.Cos(math.Pi/float64(n)*(float64(i)+0.5)*float64(k))
+		}
+		scale := math.Sqrt(2.0 / float64(n))
+		if k == 0 {
+			scale = math.Sqrt(1.0 / float64(n))
+		}
+		output[k] = sum * scale
+	}
+	return output
+}
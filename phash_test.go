@@ -0,0 +1,98 @@
+package openai
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestComputeImageHashStableUnderReencode(t *testing.T) {
+	img := solidImage(64, 64, color.RGBA{R: 20, G: 120, B: 200, A: 255})
+
+	var buf1 bytes.Buffer
+	if err := png.Encode(&buf1, img); err != nil {
+		t.Fatalf("encoding: %v", err)
+	}
+	h1, err := computeImageHash(bytes.NewReader(buf1.Bytes()))
+	if err != nil {
+		t.Fatalf("computeImageHash: %v", err)
+	}
+
+	// Re-encoding the same pixels (even via a fresh RGBA copy) should
+	// produce an identical or near-identical hash.
+	copyImg := image.NewRGBA(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			copyImg.Set(x, y, img.At(x, y))
+		}
+	}
+	var buf2 bytes.Buffer
+	if err := png.Encode(&buf2, copyImg); err != nil {
+		t.Fatalf("encoding: %v", err)
+	}
+	h2, err := computeImageHash(bytes.NewReader(buf2.Bytes()))
+	if err != nil {
+		t.Fatalf("computeImageHash: %v", err)
+	}
+
+	if HammingDistance(h1, h2) > defaultImagePerceptualThreshold {
+		t.Fatalf("expected re-encoded identical image to hash within threshold, got distance %d",
+			HammingDistance(h1, h2))
+	}
+}
+
+func TestComputeImageHashDistinguishesDifferentImages(t *testing.T) {
+	// pHash reflects an image's structure, not its overall colour, so two
+	// solid images of different colours can hash alike. Compare a
+	// brightness pattern against its negative instead.
+	pattern := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	negative := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			v := uint8(x * y * 255 / (63 * 63))
+			pattern.Set(x, y, color.Gray{Y: v})
+			negative.Set(x, y, color.Gray{Y: 255 - v})
+		}
+	}
+
+	var patternBuf, negativeBuf bytes.Buffer
+	if err := png.Encode(&patternBuf, pattern); err != nil {
+		t.Fatalf("encoding: %v", err)
+	}
+	if err := png.Encode(&negativeBuf, negative); err != nil {
+		t.Fatalf("encoding: %v", err)
+	}
+
+	hPattern, err := computeImageHash(bytes.NewReader(patternBuf.Bytes()))
+	if err != nil {
+		t.Fatalf("computeImageHash: %v", err)
+	}
+	hNegative, err := computeImageHash(bytes.NewReader(negativeBuf.Bytes()))
+	if err != nil {
+		t.Fatalf("computeImageHash: %v", err)
+	}
+
+	if HammingDistance(hPattern, hNegative) <= defaultImagePerceptualThreshold {
+		t.Fatalf("expected visually distinct images to hash outside threshold, got distance %d",
+			HammingDistance(hPattern, hNegative))
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xFF, 0x00, 8},
+		{0xFFFFFFFFFFFFFFFF, 0, 64},
+	}
+	for _, tt := range tests {
+		if got := HammingDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("HammingDistance(%x, %x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}